@@ -0,0 +1,170 @@
+package cloudmonitoring
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"golang.org/x/sync/singleflight"
+)
+
+// Dashboard loads repeatedly hit metricDescriptors/services/projects with the
+// same query, each triggering a full multi-page fetch. These defaults bound
+// how stale a cached response is allowed to be before the upstream is queried
+// again; SLOs churn more often than the other lookups so they get a shorter TTL.
+const (
+	defaultDescriptorCacheTTL = 5 * time.Minute
+	defaultSLOCacheTTL        = time.Minute
+)
+
+// cacheSweepInterval bounds how long an entry that's never requested again
+// (e.g. a one-off autocomplete query against /metricDescriptors/, whose key
+// includes the full raw query string) can linger in the cache. Without a
+// sweep, entries are only ever reclaimed by being overwritten on the same
+// key, so the map would grow without bound for the life of the plugin process.
+const cacheSweepInterval = time.Minute
+
+// cacheEntry is the aggregated result of a (possibly multi-page) resource
+// call, cached verbatim so a hit can be served without re-decoding pages.
+type cacheEntry struct {
+	responses []json.RawMessage
+	header    http.Header
+	encoding  string
+	code      int
+}
+
+// requestError preserves the HTTP status code a failed resource call should
+// be reported with when it comes back out of the singleflight group.
+type requestError struct {
+	code int
+	err  error
+}
+
+func (e *requestError) Error() string { return e.err.Error() }
+func (e *requestError) Unwrap() error { return e.err }
+
+// responseCache is an in-memory TTL cache for assembled resource responses,
+// deduplicating concurrent identical requests with a singleflight group so
+// only one of them reaches the upstream API.
+type responseCache struct {
+	mu      sync.RWMutex
+	entries map[string]cachedEntryWithDeadline
+	group   singleflight.Group
+}
+
+type cachedEntryWithDeadline struct {
+	cacheEntry
+	expiresAt time.Time
+}
+
+func newResponseCache() *responseCache {
+	c := &responseCache{entries: map[string]cachedEntryWithDeadline{}}
+	go c.sweepExpiredLoop()
+	return c
+}
+
+// resourceCache backs every resourceHandler in this package. It's a package
+// variable, rather than a field on Service, so it's shared across datasource
+// instances the same way the underlying HTTP clients are cached per-UID.
+var resourceCache = newResponseCache()
+
+// sweepExpiredLoop periodically reclaims expired entries that a lazy delete
+// on get would otherwise never touch, since nothing is querying them anymore.
+func (c *responseCache) sweepExpiredLoop() {
+	ticker := time.NewTicker(cacheSweepInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		c.sweepExpired()
+	}
+}
+
+func (c *responseCache) sweepExpired() {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func (c *responseCache) get(key string) (cacheEntry, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	c.mu.RUnlock()
+	if !ok {
+		return cacheEntry{}, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		c.mu.Lock()
+		delete(c.entries, key)
+		c.mu.Unlock()
+		return cacheEntry{}, false
+	}
+	return entry.cacheEntry, true
+}
+
+func (c *responseCache) set(key string, entry cacheEntry, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = cachedEntryWithDeadline{cacheEntry: entry, expiresAt: time.Now().Add(ttl)}
+}
+
+func (c *responseCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]cachedEntryWithDeadline{}
+}
+
+// dataSourceUID returns the UID of the datasource instance serving req, or
+// the empty string if it can't be resolved from the request's plugin context.
+func dataSourceUID(req *http.Request) string {
+	if pluginCtx := httpadapter.PluginConfigFromContext(req.Context()); pluginCtx.DataSourceInstanceSettings != nil {
+		return pluginCtx.DataSourceInstanceSettings.UID
+	}
+	return ""
+}
+
+// cacheKey identifies a resource call by everything that can change its
+// result: the datasource instance, which subDataSource it targets, and the
+// request path/query the client sent.
+func cacheKey(req *http.Request, subDataSource string) string {
+	return strings.Join([]string{dataSourceUID(req), subDataSource, req.URL.Path, req.URL.RawQuery}, "|")
+}
+
+// cacheTTL picks the TTL for a resource path. SLOs are looked up far less
+// often but also change more often (error budgets move continuously), so
+// they get a shorter TTL than metric/service/project descriptors.
+func cacheTTL(path string) time.Duration {
+	if strings.HasPrefix(path, "/slo-services/") {
+		return defaultSLOCacheTTL
+	}
+	return defaultDescriptorCacheTTL
+}
+
+func bypassesCache(req *http.Request) bool {
+	return req.Header.Get("Cache-Control") == "no-cache"
+}
+
+// isSuccessStatus reports whether an upstream response code is eligible to be
+// cached. Non-2xx responses are never cached, even though the processX
+// decoders don't themselves inspect the status code.
+func isSuccessStatus(code int) bool {
+	return code >= http.StatusOK && code < http.StatusMultipleChoices
+}
+
+// invalidateCacheHandler forces every cached resource response to be
+// refreshed on next request, e.g. after an IAM or project change makes stale
+// results actively wrong rather than just out of date.
+func invalidateCacheHandler(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		writeResponse(rw, http.StatusMethodNotAllowed, "only POST is supported")
+		return
+	}
+	resourceCache.invalidate()
+	writeResponse(rw, http.StatusOK, "cache invalidated")
+}
@@ -0,0 +1,77 @@
+package cloudmonitoring
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeProcessResponse is a minimal processResponse for tests that don't care
+// about the real metricDescriptor/service/SLO/project decoding: it just
+// wraps the raw body as a single-page result with no pagination token.
+func fakeProcessResponse(body []byte, results []json.RawMessage) ([]json.RawMessage, string, error) {
+	return append(results, json.RawMessage(body)), "", nil
+}
+
+func TestIsSuccessStatus(t *testing.T) {
+	cases := []struct {
+		code int
+		want bool
+	}{
+		{http.StatusOK, true},
+		{http.StatusNoContent, true},
+		{http.StatusMultipleChoices - 1, true},
+		{http.StatusMultipleChoices, false},
+		{http.StatusForbidden, false},
+		{http.StatusTooManyRequests, false},
+		{http.StatusServiceUnavailable, false},
+		{http.StatusContinue, false},
+	}
+	for _, c := range cases {
+		if got := isSuccessStatus(c.code); got != c.want {
+			t.Errorf("isSuccessStatus(%d) = %v, want %v", c.code, got, c.want)
+		}
+	}
+}
+
+func TestDoRequestDoesNotCacheNonSuccessResponses(t *testing.T) {
+	resourceCache = newResponseCache()
+
+	var calls int
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer upstream.Close()
+
+	newReq := func() *http.Request {
+		req := httptest.NewRequest(http.MethodGet, upstream.URL+"/projects", nil)
+		// httptest.NewRequest builds a server-side request; http.Client.Do
+		// refuses to send one with RequestURI set.
+		req.RequestURI = ""
+		return req
+	}
+
+	rec := httptest.NewRecorder()
+	doRequest(rec, newReq(), upstream.Client(), fakeProcessResponse, "shared-key", time.Minute)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("first response: got code %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	rec = httptest.NewRecorder()
+	doRequest(rec, newReq(), upstream.Client(), fakeProcessResponse, "shared-key", time.Minute)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("second response: got code %d, want %d (failed response must not have been cached)", rec.Code, http.StatusOK)
+	}
+	if calls != 2 {
+		t.Fatalf("got %d upstream calls, want 2 (a cached 503 would have served the second request without calling upstream again)", calls)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"compress/flate"
 	"compress/gzip"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -12,10 +13,13 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/andybalholm/brotli"
 	"github.com/grafana/grafana-google-sdk-go/pkg/utils"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"github.com/klauspost/compress/zstd"
 )
 
 // nameExp matches the part after the last '/' symbol
@@ -23,6 +27,21 @@ var nameExp = regexp.MustCompile(`([^\/]*)\/*$`)
 
 const resourceManagerPath = "/v1/projects"
 
+// defaultResourceCallTimeout bounds how long a single resource call (which may
+// page through many upstream requests) is allowed to run when the datasource
+// doesn't configure its own resourceCallTimeout.
+const defaultResourceCallTimeout = 30 * time.Second
+
+// requestIDHeader lets the frontend tag a resource call so it can be aborted
+// later via the /cancel/{id} route, without waiting for the paging loop to
+// finish enumerating a wide GCP org.
+const requestIDHeader = "X-Grafana-Request-Id"
+
+// inFlightCancels tracks the cancel funcs for resource calls that were tagged
+// with requestIDHeader, keyed by cancelKey (datasource UID + the header's
+// value) so one datasource can't cancel another's in-flight call.
+var inFlightCancels sync.Map // map[string]context.CancelFunc
+
 type processResponse func(body []byte, results []json.RawMessage) ([]json.RawMessage, string, error)
 
 func (s *Service) registerRoutes(mux *http.ServeMux) {
@@ -32,6 +51,44 @@ func (s *Service) registerRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/services/", s.resourceHandler(cloudMonitor, processServices))
 	mux.HandleFunc("/slo-services/", s.resourceHandler(cloudMonitor, processSLOs))
 	mux.HandleFunc("/projects", s.resourceHandler(resourceManager, processProjects))
+
+	mux.HandleFunc("/cancel/", cancelHandler)
+	mux.HandleFunc("/cache/invalidate", invalidateCacheHandler)
+
+	customRoutesMu.Lock()
+	routes := append([]ResourceRoute(nil), customRoutes...)
+	customRoutesMu.Unlock()
+	for _, route := range routes {
+		mux.HandleFunc(route.Pattern, s.resourceHandler(route.SubDataSource, route.ProcessResponse))
+	}
+}
+
+// cancelHandler trips the cancel func registered for the X-Grafana-Request-Id
+// of an in-flight resource call, if any, causing its paging loop to return
+// early on the next iteration instead of fetching every remaining page.
+func cancelHandler(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodDelete {
+		writeResponse(rw, http.StatusMethodNotAllowed, "only DELETE is supported")
+		return
+	}
+
+	id := strings.TrimPrefix(req.URL.Path, "/cancel/")
+	if id == "" {
+		writeResponse(rw, http.StatusBadRequest, "missing request id")
+		return
+	}
+
+	if cancel, ok := inFlightCancels.LoadAndDelete(cancelKey(req, id)); ok {
+		cancel.(context.CancelFunc)()
+	}
+	writeResponse(rw, http.StatusOK, "cancelled")
+}
+
+// cancelKey namespaces a client-supplied X-Grafana-Request-Id by the
+// requesting datasource instance, so one datasource can't cancel another's
+// in-flight resource call by sending a guessed or reused request id.
+func cancelKey(req *http.Request, requestID string) string {
+	return dataSourceUID(req) + "|" + requestID
 }
 
 func getGCEDefaultProject(rw http.ResponseWriter, req *http.Request) {
@@ -45,35 +102,92 @@ func getGCEDefaultProject(rw http.ResponseWriter, req *http.Request) {
 
 func (s *Service) resourceHandler(subDataSource string, responseFn processResponse) func(rw http.ResponseWriter, req *http.Request) {
 	return func(rw http.ResponseWriter, req *http.Request) {
+		key := cacheKey(req, subDataSource)
+		ttl := cacheTTL(req.URL.Path)
+
 		client, code, err := s.setRequestVariables(req, subDataSource)
 		if err != nil {
 			writeResponse(rw, code, fmt.Sprintf("unexpected error %v", err))
 			return
 		}
-		doRequest(rw, req, client, responseFn)
+
+		ctx, cancel := context.WithTimeout(req.Context(), s.resourceCallTimeout(req))
+		defer cancel()
+		if reqID := req.Header.Get(requestIDHeader); reqID != "" {
+			key := cancelKey(req, reqID)
+			inFlightCancels.Store(key, cancel)
+			defer inFlightCancels.Delete(key)
+		}
+		req = req.WithContext(ctx)
+
+		if wantsEventStream(req) {
+			streamRequest(rw, req, client, responseFn)
+			return
+		}
+		doRequest(rw, req, client, responseFn, key, ttl)
 	}
 }
 
-func doRequest(rw http.ResponseWriter, req *http.Request, cli *http.Client, responseFn processResponse) http.ResponseWriter {
+// wantsEventStream reports whether the caller asked for incremental, per-page
+// delivery instead of the aggregated JSON array built by buildResponse.
+func wantsEventStream(req *http.Request) bool {
+	return strings.Contains(req.Header.Get("Accept"), "text/event-stream")
+}
+
+func doRequest(rw http.ResponseWriter, req *http.Request, cli *http.Client, responseFn processResponse, cacheKey string, ttl time.Duration) http.ResponseWriter {
 	if responseFn == nil {
 		writeResponse(rw, http.StatusInternalServerError, "responseFn should not be nil")
 		return rw
 	}
 
-	responses, headers, encoding, code, err := loopRequest(req, cli, responseFn)
+	bypassCache := bypassesCache(req)
+	if !bypassCache {
+		if entry, ok := resourceCache.get(cacheKey); ok {
+			return writeCachedEntry(rw, entry)
+		}
+	}
+
+	// singleflight collapses concurrent identical requests (e.g. several
+	// dashboard panels loading the same service list at once) into a single
+	// upstream call; every caller gets the same result.
+	result, err, _ := resourceCache.group.Do(cacheKey, func() (interface{}, error) {
+		responses, headers, encoding, code, err := loopRequest(req, cli, responseFn)
+		if err != nil {
+			return cacheEntry{}, &requestError{code: code, err: err}
+		}
+
+		entry := cacheEntry{responses: responses, header: headers, encoding: encoding, code: code}
+		// A non-2xx upstream response (403 during IAM propagation, 429
+		// throttling, 503, ...) still decodes "successfully" into an empty
+		// result, since none of the processX decoders look at the status
+		// code. Caching that would serve an empty dropdown for the rest of
+		// the TTL even after the underlying issue clears.
+		if !bypassCache && isSuccessStatus(code) {
+			resourceCache.set(cacheKey, entry, ttl)
+		}
+		return entry, nil
+	})
 	if err != nil {
-		writeResponse(rw, code, fmt.Sprintf("unexpected error %v", err))
+		reqErr, ok := err.(*requestError)
+		if !ok {
+			reqErr = &requestError{code: http.StatusInternalServerError, err: err}
+		}
+		writeResponse(rw, reqErr.code, fmt.Sprintf("unexpected error %v", reqErr.err))
 		return rw
 	}
 
-	body, errcode, err := buildResponse(responses, encoding)
+	return writeCachedEntry(rw, result.(cacheEntry))
+}
+
+func writeCachedEntry(rw http.ResponseWriter, entry cacheEntry) http.ResponseWriter {
+	body, errcode, err := buildResponse(entry.responses, entry.encoding)
 	if err != nil {
 		writeResponse(rw, errcode, fmt.Sprintf("error formatting responose %v", err))
 		return rw
 	}
-	writeResponseBytes(rw, code, body)
+	writeResponseBytes(rw, entry.code, body)
 
-	for k, v := range headers {
+	for k, v := range entry.header {
 		rw.Header().Set(k, v[0])
 		for _, v := range v[1:] {
 			rw.Header().Add(k, v)
@@ -200,6 +314,12 @@ func decode(encoding string, original io.ReadCloser) ([]byte, int, error) {
 		}()
 	case "br":
 		reader = brotli.NewReader(original)
+	case "zstd":
+		reader, err = zstd.NewReader(original)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		defer reader.(*zstd.Decoder).Close()
 	case "":
 		reader = original
 	default:
@@ -227,6 +347,11 @@ func encode(encoding string, body []byte) ([]byte, int, error) {
 		}
 	case "br":
 		writer = brotli.NewWriter(writer)
+	case "zstd":
+		writer, err = zstd.NewWriter(writer, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
 	case "":
 	default:
 		return nil, http.StatusInternalServerError, fmt.Errorf("unexpected encoding type %v", encoding)
@@ -264,6 +389,10 @@ func loopRequest(req *http.Request, cli *http.Client, responseFn processResponse
 	var encoding, token string
 
 	for {
+		if err := req.Context().Err(); err != nil {
+			return nil, nil, "", http.StatusRequestTimeout, fmt.Errorf("resource call aborted: %w", err)
+		}
+
 		res, err := cli.Do(req)
 		if err != nil {
 			return nil, nil, "", http.StatusBadRequest, err
@@ -293,6 +422,75 @@ func loopRequest(req *http.Request, cli *http.Client, responseFn processResponse
 	return responses, originalHeader, encoding, originalCode, nil
 }
 
+// streamRequest pages through the upstream resource the same way loopRequest
+// does, but writes each page to the client as a server-sent event as soon as
+// it arrives instead of buffering the full result set in memory. This keeps
+// wide GCP orgs (e.g. thousands of metric descriptors) from blocking the UI
+// until every page has been fetched.
+func streamRequest(rw http.ResponseWriter, req *http.Request, cli *http.Client, responseFn processResponse) {
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		writeResponse(rw, http.StatusInternalServerError, "streaming unsupported by response writer")
+		return
+	}
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.Header().Set("Connection", "keep-alive")
+	rw.WriteHeader(http.StatusOK)
+
+	token := ""
+	for {
+		if token != "" {
+			query := req.URL.Query()
+			query.Set("pageToken", token)
+			req.URL.RawQuery = query.Encode()
+		}
+
+		if err := req.Context().Err(); err != nil {
+			writeSSEEvent(rw, flusher, "error", fmt.Sprintf(`{"error":%q}`, err.Error()))
+			return
+		}
+
+		res, err := cli.Do(req)
+		if err != nil {
+			writeSSEEvent(rw, flusher, "error", fmt.Sprintf(`{"error":%q}`, err.Error()))
+			return
+		}
+
+		page, nextToken, _, err := processData(res.Body, res.Header.Get("Content-Encoding"), []json.RawMessage{}, responseFn)
+		if closeErr := res.Body.Close(); closeErr != nil {
+			slog.Warn("Failed to close response body", "err", closeErr)
+		}
+		if err != nil {
+			writeSSEEvent(rw, flusher, "error", fmt.Sprintf(`{"error":%q}`, err.Error()))
+			return
+		}
+
+		body, err := json.Marshal(page)
+		if err != nil {
+			writeSSEEvent(rw, flusher, "error", fmt.Sprintf(`{"error":%q}`, err.Error()))
+			return
+		}
+		writeSSEEvent(rw, flusher, "page", string(body))
+
+		token = nextToken
+		if token == "" {
+			break
+		}
+	}
+
+	writeSSEEvent(rw, flusher, "end", "{}")
+}
+
+// writeSSEEvent writes a single server-sent event frame and flushes it to the
+// client immediately so pages are delivered incrementally rather than
+// buffered by the response writer.
+func writeSSEEvent(rw http.ResponseWriter, flusher http.Flusher, event, data string) {
+	fmt.Fprintf(rw, "event: %s\ndata: %s\n\n", event, data)
+	flusher.Flush()
+}
+
 func buildResponse(responses []json.RawMessage, encoding string) ([]byte, int, error) {
 	body, err := json.Marshal(responses)
 	if err != nil {
@@ -302,6 +500,34 @@ func buildResponse(responses []json.RawMessage, encoding string) ([]byte, int, e
 	return encode(encoding, body)
 }
 
+// resourceCallTimeoutSettings is the subset of a datasource's jsonData this
+// package understands for configuring resourceCallTimeout, e.g.
+// `{"resourceCallTimeout": "45s"}`.
+type resourceCallTimeoutSettings struct {
+	ResourceCallTimeout string `json:"resourceCallTimeout"`
+}
+
+// resourceCallTimeout returns the per-resource-call deadline configured on the
+// datasource via the resourceCallTimeout setting, falling back to
+// defaultResourceCallTimeout when unset or invalid.
+func (s *Service) resourceCallTimeout(req *http.Request) time.Duration {
+	pluginCtx := httpadapter.PluginConfigFromContext(req.Context())
+	if pluginCtx.DataSourceInstanceSettings == nil {
+		return defaultResourceCallTimeout
+	}
+
+	var settings resourceCallTimeoutSettings
+	if err := json.Unmarshal(pluginCtx.DataSourceInstanceSettings.JSONData, &settings); err != nil || settings.ResourceCallTimeout == "" {
+		return defaultResourceCallTimeout
+	}
+
+	timeout, err := time.ParseDuration(settings.ResourceCallTimeout)
+	if err != nil || timeout <= 0 {
+		return defaultResourceCallTimeout
+	}
+	return timeout
+}
+
 func (s *Service) setRequestVariables(req *http.Request, subDataSource string) (*http.Client, int, error) {
 	slog.Debug("Received resource call", "url", req.URL.String(), "method", req.Method)
 
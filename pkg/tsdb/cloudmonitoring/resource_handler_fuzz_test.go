@@ -0,0 +1,42 @@
+package cloudmonitoring
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// codecs are every Content-Encoding decode/encode understand. Exercised in a
+// fixed order per fuzz input so a failure always names a single codec.
+var codecs = []string{"", "gzip", "deflate", "br", "zstd"}
+
+func FuzzEncodeDecodeRoundTrip(f *testing.F) {
+	seeds := [][]byte{
+		nil,
+		[]byte(""),
+		[]byte("a"),
+		[]byte(`{"hello":"world"}`),
+		bytes.Repeat([]byte("grafana-cloudmonitoring"), 100),
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		for _, codec := range codecs {
+			encoded, code, err := encode(codec, body)
+			if err != nil {
+				t.Fatalf("encode(%q): unexpected error (code %d): %v", codec, code, err)
+			}
+
+			decoded, code, err := decode(codec, io.NopCloser(bytes.NewReader(encoded)))
+			if err != nil {
+				t.Fatalf("decode(%q): unexpected error (code %d): %v", codec, code, err)
+			}
+
+			if !bytes.Equal(decoded, body) {
+				t.Fatalf("round trip mismatch for codec %q: got %q, want %q", codec, decoded, body)
+			}
+		}
+	})
+}
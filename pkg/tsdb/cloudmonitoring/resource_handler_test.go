@@ -0,0 +1,53 @@
+package cloudmonitoring
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLoopRequestAbortsOnCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/projects", nil).WithContext(ctx)
+
+	_, _, _, code, err := loopRequest(req, http.DefaultClient, fakeProcessResponse)
+	if err == nil {
+		t.Fatal("expected an error for a canceled context, got nil")
+	}
+	if code != http.StatusRequestTimeout {
+		t.Fatalf("got code %d, want %d", code, http.StatusRequestTimeout)
+	}
+}
+
+func TestStreamRequestAbortsOnCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/metricDescriptors/", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+
+	streamRequest(rec, req, http.DefaultClient, fakeProcessResponse)
+
+	if !strings.Contains(rec.Body.String(), "event: error") {
+		t.Fatalf("expected an error SSE event for a canceled context, got body: %q", rec.Body.String())
+	}
+}
+
+func TestCancelKeyNamespacesByDataSourceUID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/projects", nil)
+
+	got := cancelKey(req, "request-id")
+	want := dataSourceUID(req) + "|request-id"
+	if got != want {
+		t.Fatalf("cancelKey() = %q, want %q", got, want)
+	}
+
+	// Without a resolvable datasource, the key still namespaces on the
+	// (empty) UID rather than collapsing to the bare request id, so it can
+	// never match a key registered under a datasource that has a UID.
+	if got != "|request-id" {
+		t.Fatalf("cancelKey() with no datasource = %q, want %q", got, "|request-id")
+	}
+}
@@ -0,0 +1,74 @@
+package cloudmonitoring
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ProcessResponse is the exported form of processResponse, for third-party
+// resource routes registered via RegisterResourceRoute.
+type ProcessResponse = processResponse
+
+// SelectableValue is the exported form of selectableValue, so a custom
+// ProcessResponse can build the same {value, label} pairs the built-in
+// services/projects/SLO routes return.
+type SelectableValue = selectableValue
+
+// ResourceRoute pairs a URL pattern with the subDataSource whose HTTP client
+// should serve it and the decoder that turns each page of that upstream's
+// response into results.
+type ResourceRoute struct {
+	Pattern         string
+	SubDataSource   string
+	ProcessResponse ProcessResponse
+}
+
+var (
+	customRoutesMu sync.Mutex
+	customRoutes   []ResourceRoute
+)
+
+// builtinResourcePatterns are the patterns registerRoutes wires up itself
+// (resource_handler.go). RegisterResourceRoute must reject any of these too,
+// not just previously-registered custom patterns, since registerRoutes calls
+// mux.HandleFunc for them unconditionally and a duplicate pattern panics.
+var builtinResourcePatterns = map[string]struct{}{
+	"/gceDefaultProject": {},
+	"/metricDescriptors/": {},
+	"/services/":          {},
+	"/slo-services/":      {},
+	"/projects":           {},
+	"/cancel/":            {},
+	"/cache/invalidate":   {},
+}
+
+// RegisterResourceRoute lets sibling packages and forks (cloud logging, cloud
+// trace, ...) add their own resource endpoints - e.g. /logScopes/,
+// /monitoredResourceDescriptors/, or /uptimeChecks/ - without editing the
+// core registerRoutes, mirroring how the built-in cloudMonitor and
+// resourceManager routes are wired up.
+//
+// It's a package-level registry rather than a *Service method: routes are
+// registered once, at init time, and apply to every Service's mux, the same
+// way http.ServeMux itself has no notion of per-instance handler sets here.
+// Registering the same pattern twice - including one that collides with a
+// built-in pattern - is a programmer error - http.ServeMux panics on a
+// duplicate pattern - so this rejects it instead of panicking later inside
+// registerRoutes.
+func RegisterResourceRoute(pattern, subDataSource string, responseFn ProcessResponse) error {
+	if _, ok := builtinResourcePatterns[pattern]; ok {
+		return fmt.Errorf("resource route %q collides with a built-in route", pattern)
+	}
+
+	customRoutesMu.Lock()
+	defer customRoutesMu.Unlock()
+
+	for _, route := range customRoutes {
+		if route.Pattern == pattern {
+			return fmt.Errorf("resource route %q is already registered", pattern)
+		}
+	}
+
+	customRoutes = append(customRoutes, ResourceRoute{Pattern: pattern, SubDataSource: subDataSource, ProcessResponse: responseFn})
+	return nil
+}
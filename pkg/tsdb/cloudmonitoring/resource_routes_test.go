@@ -0,0 +1,25 @@
+package cloudmonitoring
+
+import "testing"
+
+func TestRegisterResourceRouteRejectsBuiltinCollision(t *testing.T) {
+	for pattern := range builtinResourcePatterns {
+		if err := RegisterResourceRoute(pattern, cloudMonitor, fakeProcessResponse); err == nil {
+			t.Errorf("RegisterResourceRoute(%q): expected error for a pattern that collides with a built-in route, got nil", pattern)
+		}
+	}
+}
+
+func TestRegisterResourceRouteRejectsDuplicateCustomPattern(t *testing.T) {
+	const pattern = "/logScopes/"
+	customRoutesMu.Lock()
+	customRoutes = nil
+	customRoutesMu.Unlock()
+
+	if err := RegisterResourceRoute(pattern, cloudMonitor, fakeProcessResponse); err != nil {
+		t.Fatalf("first registration of %q: unexpected error: %v", pattern, err)
+	}
+	if err := RegisterResourceRoute(pattern, cloudMonitor, fakeProcessResponse); err == nil {
+		t.Fatalf("second registration of %q: expected error, got nil", pattern)
+	}
+}